@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/sirupsen/logrus"
+	trace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// DecodeProto reads OTLP ExportTraceServiceRequest protobuf messages directly
+// from a file exported by an OTLP exporter, bypassing the JSON->pdata->proto
+// round-trip TranslateCollectorTraces does for JSON input. Messages are
+// expected to be varint length-prefixed (as protodelim.MarshalTo writes
+// them); if the data can't be parsed as at least two such frames, the whole
+// file is treated as a single unframed protobuf blob instead.
+func DecodeProto(logger *logrus.Logger, file io.Reader) <-chan *trace.ExportTraceServiceRequest {
+	out := make(chan *trace.ExportTraceServiceRequest)
+
+	go func() {
+		defer close(out)
+
+		data, err := io.ReadAll(file)
+
+		if err != nil {
+			logger.WithError(err).Error("Failed to read file")
+			return
+		}
+
+		if msgs, ok := decodeLengthDelimited(data); ok {
+			for _, req := range msgs {
+				out <- req
+			}
+
+			return
+		}
+
+		// fall back to treating the whole file as a single unframed message
+		req := &trace.ExportTraceServiceRequest{}
+
+		if err := proto.Unmarshal(data, req); err != nil {
+			logger.WithError(err).Error("Failed to unmarshal ExportTraceServiceRequest")
+			return
+		}
+
+		out <- req
+	}()
+
+	return out
+}
+
+// decodeLengthDelimited parses a sequence of varint length-prefixed protobuf
+// messages. It returns ok=false if the data can't be fully consumed as such
+// a sequence, so the caller can fall back to single-blob decoding. A single
+// unframed message's leading tag+length bytes can coincidentally parse as a
+// valid one-frame sequence spanning the whole buffer, so we also require at
+// least two frames before trusting this as the real framing.
+func decodeLengthDelimited(data []byte) ([]*trace.ExportTraceServiceRequest, bool) {
+	if len(data) == 0 {
+		return nil, false
+	}
+
+	var msgs []*trace.ExportTraceServiceRequest
+
+	for len(data) > 0 {
+		size, n := binary.Uvarint(data)
+
+		if n <= 0 || uint64(n)+size > uint64(len(data)) {
+			return nil, false
+		}
+
+		data = data[n:]
+
+		req := &trace.ExportTraceServiceRequest{}
+
+		if err := proto.Unmarshal(data[:size], req); err != nil {
+			return nil, false
+		}
+
+		msgs = append(msgs, req)
+		data = data[size:]
+	}
+
+	if len(msgs) < 2 {
+		return nil, false
+	}
+
+	return msgs, true
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	trace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecodeProtoLengthDelimited(t *testing.T) {
+	logger := logrus.New()
+
+	var buf bytes.Buffer
+
+	msg := &trace.ExportTraceServiceRequest{}
+
+	assert.NoError(t, protodelim.MarshalTo(&buf, msg))
+	assert.NoError(t, protodelim.MarshalTo(&buf, msg))
+
+	ch := DecodeProto(logger, &buf)
+
+	var count int
+
+	for range ch {
+		count++
+	}
+
+	assert.Equal(t, count, 2)
+}
+
+func TestDecodeProtoRejectsAmbiguousSingleFrame(t *testing.T) {
+	logger := logrus.New()
+
+	var buf bytes.Buffer
+
+	msg := &trace.ExportTraceServiceRequest{}
+
+	assert.NoError(t, protodelim.MarshalTo(&buf, msg))
+
+	// Only one length-delimited frame isn't enough evidence that the file is
+	// really framed rather than an unframed blob that happens to parse this
+	// way, so DecodeProto falls back to single-blob decoding, which fails on
+	// these bytes (the leftover length-prefix byte isn't a valid message).
+	ch := DecodeProto(logger, &buf)
+
+	var count int
+
+	for range ch {
+		count++
+	}
+
+	assert.Equal(t, count, 0)
+}
+
+func TestDecodeProtoSingleBlob(t *testing.T) {
+	logger := logrus.New()
+
+	msg := &trace.ExportTraceServiceRequest{}
+
+	b, err := proto.Marshal(msg)
+	assert.NoError(t, err)
+
+	ch := DecodeProto(logger, bytes.NewReader(b))
+
+	var count int
+
+	for range ch {
+		count++
+	}
+
+	assert.Equal(t, count, 1)
+}
@@ -24,18 +24,42 @@ func main() {
 	var batch int
 	var sleep time.Duration
 	var start time.Duration
+	var preserveDuration bool
 	var verbosity uint
 	var checkDIAndExit bool
+	var signalType string
+	var format string
+	var dir string
+	var sweepInterval time.Duration
+	var workers int
+	var maxBatchBytes int
+	var retryMaxAttempts int
+	var retryInitialDelay time.Duration
+	var retryMaxDelay time.Duration
+	var listen string
+	var listenHTTP string
 
 	flag.StringVar(&path, "path", "", "Path to the file containing OTLP JSON formatted events")
+	flag.StringVar(&listen, "listen", "", "Address to listen on for incoming OTLP gRPC traffic (e.g. :4317), forwarding it to Honeycomb instead of reading -path")
+	flag.StringVar(&listenHTTP, "listen-http", "", "Address to listen on for incoming OTLP/HTTP traffic (e.g. :4318), forwarding it to Honeycomb instead of reading -path")
+	flag.StringVar(&dir, "dir", "", "Path to a directory to sweep continuously for OTLP files, instead of importing a single -path file")
+	flag.DurationVar(&sweepInterval, "sweep-interval", 5*time.Second, "How often to scan -dir for new files")
+	flag.IntVar(&workers, "workers", 10, "The number of files to process concurrently in -dir mode")
+	flag.StringVar(&format, "format", "json", "The format of the file(s): json or proto (length-delimited or single-blob OTLP protobuf, traces only)")
+	flag.StringVar(&signalType, "signal", "traces", "The OTLP signal contained in the file(s): traces, metrics, or logs")
 	flag.StringVar(&key, "key", "", "The Honeycomb API key to send the events")
 	flag.StringVar(&dataset, "dataset", "", "The Honeycomb dataset to send the events to, if not specified, assumes the destination is an environment")
 	flag.StringVar(&host, "host", "https://api.honeycomb.io", "The Honeycomb host to send the events to, if not specified, assumes the destination is an environment")
 	flag.IntVar(&batch, "batch", 200, "The number of events to send in a row before pausing")
+	flag.IntVar(&maxBatchBytes, "max-batch-bytes", 5*1024*1024, "The approximate number of bytes to send in a row before pausing, in addition to -batch")
 	flag.DurationVar(&sleep, "sleep", 100*time.Millisecond, "The duration to sleep between batches")
 	flag.DurationVar(&start, "start", 0, "The duration ago to start the events from")
+	flag.BoolVar(&preserveDuration, "preserve-duration", false, "Warn if sliding the trace to -start would push its earliest event outside Honeycomb's ingest window")
 	flag.UintVar(&verbosity, "verbosity", 4, "The verbosity level of the output")
 	flag.BoolVar(&checkDIAndExit, "check-di-and-exit", false, "if present, we'll exit immediately - used in CI to check if DI is valid")
+	flag.IntVar(&retryMaxAttempts, "retry-max-attempts", 5, "The number of times to retry an event that failed with a retryable error before giving up on it")
+	flag.DurationVar(&retryInitialDelay, "retry-initial-delay", 500*time.Millisecond, "The delay before the first retry of a failed event")
+	flag.DurationVar(&retryMaxDelay, "retry-max-delay", 30*time.Second, "The maximum delay between retries of a failed event")
 
 	flag.Parse()
 
@@ -46,8 +70,8 @@ func main() {
 		os.Exit(0)
 	}
 
-	if path == "" {
-		logger.Fatal("File Path is required")
+	if path == "" && dir == "" && listen == "" && listenHTTP == "" {
+		logger.Fatal("One of -path, -dir, -listen, or -listen-http is required")
 	}
 
 	if key == "" {
@@ -66,29 +90,122 @@ func main() {
 		logger.WithError(err).Fatal("Failed to initialize honeycomb")
 	}
 
-	MonitorLibhoneyResponses(logger)
+	retryTracker := NewRetryTracker(logger, RetryConfig{
+		MaxAttempts:  retryMaxAttempts,
+		InitialDelay: retryInitialDelay,
+		MaxDelay:     retryMaxDelay,
+	})
+
+	MonitorLibhoneyResponses(logger, retryTracker)
+
+	opts := ImportOptions{
+		Dataset:          dataset,
+		Format:           format,
+		Signal:           signalType,
+		Batch:            batch,
+		MaxBatchBytes:    maxBatchBytes,
+		Start:            start,
+		PreserveDuration: preserveDuration,
+		Sleep:            sleep,
+	}
+
+	if dir != "" {
+		RunSweep(logger, client, dir, sweepInterval, workers, opts, retryTracker)
+
+		return
+	}
+
+	if listen != "" || listenHTTP != "" {
+		runReceiver(logger, client, listen, listenHTTP, opts, retryTracker)
+
+		return
+	}
+
+	signal := make(chan any)
+	defer close(signal)
+
+	Spinner("Working", signal)
 
+	count, err := ProcessFile(logger, client, path, opts)
+
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to process file")
+	}
+
+	retryTracker.Wait()
+	libhoney.Close()
+
+	logger.Infof("Finished: sent %d events, %d permanently failed", count, retryTracker.PermanentlyFailed())
+}
+
+// ImportOptions bundles the per-run settings that are the same whether we're
+// importing a single -path file or sweeping a -dir of them.
+type ImportOptions struct {
+	Dataset          string
+	Format           string
+	Signal           string
+	Batch            int
+	MaxBatchBytes    int
+	Start            time.Duration
+	PreserveDuration bool
+	Sleep            time.Duration
+}
+
+// ProcessFile runs the decode -> translate -> produce pipeline for a single
+// file and returns the number of events sent.
+func ProcessFile(logger *logrus.Logger, client *libhoney.Client, path string, opts ImportOptions) (int, error) {
 	file, err := os.Open(path)
 
 	if err != nil {
-		logger.WithError(err).Fatal("Failed to open file")
+		return 0, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	defer func() {
-		if err = file.Close(); err != nil {
+		if err := file.Close(); err != nil {
 			logger.WithError(err).Error("Failed to close file")
 		}
 	}()
 
-	signal := make(chan any)
-	defer close(signal)
+	var translated <-chan *husky.TranslateOTLPRequestResult
 
-	Spinner("Working", signal)
+	if opts.Format == "proto" {
+		if opts.Signal != "traces" {
+			return 0, fmt.Errorf("-format proto only supports -signal traces")
+		}
 
-	json := DecodeJSON(logger, file)
-	exports := TranslateCollectorTraces(logger, json)
-	translated := TranslateTraceRequest(logger, exports)
-	events := ProduceEvents(logger, client, dataset, batch, start, sleep, translated)
+		exports := DecodeProto(logger, file)
+		translated = TranslateTraceRequest(logger, exports)
+	} else {
+		rawMessages := DecodeJSON(logger, file)
+
+		switch opts.Signal {
+		case "traces":
+			exports := TranslateCollectorTraces(logger, rawMessages)
+			translated = TranslateTraceRequest(logger, exports)
+		case "metrics":
+			exports := TranslateCollectorMetrics(logger, rawMessages)
+			translated = TranslateMetricsRequest(logger, exports)
+		case "logs":
+			exports := TranslateCollectorLogs(logger, rawMessages)
+			translated = TranslateLogsRequest(logger, exports)
+		default:
+			return 0, fmt.Errorf("unknown signal %q, must be one of traces, metrics, logs", opts.Signal)
+		}
+	}
+
+	var adjustment time.Duration
+
+	if opts.Start > 0 {
+		var minTimestamp time.Time
+
+		translated, adjustment, minTimestamp = BufferAndComputeAdjustment(logger, opts.Start, translated)
+
+		if opts.PreserveDuration {
+			WarnIfOutsideIngestWindow(logger, minTimestamp.Add(adjustment))
+		}
+	}
+
+	events := ProduceEvents(logger, client, opts.Dataset, opts.Batch, opts.MaxBatchBytes, adjustment, opts.Sleep, translated)
 
 	var count int
 
@@ -96,9 +213,7 @@ func main() {
 		count++
 	}
 
-	libhoney.Close()
-
-	logger.Infof("Finished: sent %d events", count)
+	return count, nil
 }
 
 func DecodeJSON(logger *logrus.Logger, file io.Reader) <-chan *json.RawMessage {
@@ -165,6 +280,9 @@ func TranslateCollectorTraces(logger *logrus.Logger, in <-chan *json.RawMessage)
 	return out
 }
 
+// TranslateTraceRequest keeps reading in even after a bad request, since
+// runReceiver wires it directly to a live gRPC/HTTP server: bailing out here
+// would leave nothing draining the channel and wedge every future request.
 func TranslateTraceRequest(logger *logrus.Logger, in <-chan *trace.ExportTraceServiceRequest) <-chan *husky.TranslateOTLPRequestResult {
 	out := make(chan *husky.TranslateOTLPRequestResult)
 
@@ -179,7 +297,7 @@ func TranslateTraceRequest(logger *logrus.Logger, in <-chan *trace.ExportTraceSe
 
 			if err != nil {
 				logger.WithError(err).Error("Failed to translate trace")
-				break
+				continue
 			}
 
 			out <- hny
@@ -189,34 +307,32 @@ func TranslateTraceRequest(logger *logrus.Logger, in <-chan *trace.ExportTraceSe
 	return out
 }
 
-func ProduceEvents(logger *logrus.Logger, client *libhoney.Client, dataset string, batch int, start, sleep time.Duration, in <-chan *husky.TranslateOTLPRequestResult) <-chan *libhoney.Event {
+func ProduceEvents(logger *logrus.Logger, client *libhoney.Client, dataset string, batch, maxBatchBytes int, adjustment, sleep time.Duration, in <-chan *husky.TranslateOTLPRequestResult) <-chan *libhoney.Event {
 	out := make(chan *libhoney.Event)
 
-	// this is the time that we want to start populating events from
-	begin := time.Now().Add(-1 * start)
-
-	// this is how much we need to adjust each events timestamp by
-	var adjustment time.Duration
-
 	var count int
+	var bytesSinceFlush int
 
 	go func() {
 		defer close(out)
 
 		for hny := range in {
 			for _, b := range hny.Batches {
+				// SizeBytes covers the whole husky batch, so count it once
+				// against the pacing ceiling as soon as we start draining it.
+				bytesSinceFlush += b.SizeBytes
+
 				for _, e := range b.Events {
-					// calculate the adjustment to use for the events
-					// if a start has been provided and we have not done it yet
-					if adjustment == 0 && start > 0 {
-						// this should be the earliest event in the file
-						// use this to calculate how much to adjust each timestamp by
-						adjustment = begin.Sub(e.Timestamp)
-					}
+					if count > batch || (maxBatchBytes > 0 && bytesSinceFlush > maxBatchBytes) {
+						logger.WithFields(logrus.Fields{
+							"count":       count,
+							"bytes":       bytesSinceFlush,
+							"flushReason": flushReason(count, batch, bytesSinceFlush, maxBatchBytes),
+						}).Debug("Pausing between batches")
 
-					if count > batch {
 						time.Sleep(sleep)
 						count = 0
+						bytesSinceFlush = 0
 					}
 
 					event := client.NewEvent()
@@ -229,6 +345,9 @@ func ProduceEvents(logger *logrus.Logger, client *libhoney.Client, dataset strin
 						event.Dataset = b.Dataset
 					}
 
+					// lets the response monitor re-send this exact event on a retryable failure
+					event.Metadata = &retryMetadata{event: event}
+
 					if err := event.SendPresampled(); err != nil {
 						logger.WithError(err).Error("Failed to send event")
 						continue
@@ -245,16 +364,27 @@ func ProduceEvents(logger *logrus.Logger, client *libhoney.Client, dataset strin
 	return out
 }
 
-func MonitorLibhoneyResponses(logger *logrus.Logger) {
+// flushReason describes which threshold triggered a pacing pause, for
+// logging. Count and bytes can both be over threshold at once; count wins
+// since it was the original pacing signal.
+func flushReason(count, batch, bytesSinceFlush, maxBatchBytes int) string {
+	if count > batch {
+		return "count"
+	}
+
+	if maxBatchBytes > 0 && bytesSinceFlush > maxBatchBytes {
+		return "bytes"
+	}
+
+	return ""
+}
+
+func MonitorLibhoneyResponses(logger *logrus.Logger, retryTracker *RetryTracker) {
 	r := libhoney.TxResponses()
 
 	go func() {
 		for resp := range r {
-			if resp.Err != nil {
-				logger.WithError(resp.Err).
-					WithField("response", resp).
-					Error("Failed to send event")
-			}
+			retryTracker.HandleResponse(resp)
 		}
 	}()
 }
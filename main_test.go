@@ -113,7 +113,7 @@ func TestProduceEvents(t *testing.T) {
 
 	close(in)
 
-	ch := ProduceEvents(logger, client, "", 1, 0, 0, in)
+	ch := ProduceEvents(logger, client, "", 1, 0, 0, 0, in)
 
 	var count int
 
@@ -127,3 +127,9 @@ func TestProduceEvents(t *testing.T) {
 
 	assert.Equal(t, len(events), 1)
 }
+
+func TestFlushReason(t *testing.T) {
+	assert.Equal(t, flushReason(3, 2, 0, 0), "count")
+	assert.Equal(t, flushReason(0, 2, 100, 50), "bytes")
+	assert.Equal(t, flushReason(0, 2, 0, 0), "")
+}
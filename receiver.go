@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	husky "github.com/honeycombio/husky/otlp"
+	"github.com/honeycombio/libhoney-go"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	trace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// ReceiverChannels are the channels a gRPC/HTTP OTLP receiver forwards
+// incoming requests onto. They feed directly into the same
+// TranslateTraceRequest/TranslateMetricsRequest/TranslateLogsRequest stages
+// used for file-based import, so the husky-translate -> libhoney pipeline is
+// reused verbatim for live replay.
+type ReceiverChannels struct {
+	Traces  chan *trace.ExportTraceServiceRequest
+	Metrics chan *collectormetrics.ExportMetricsServiceRequest
+	Logs    chan *collectorlogs.ExportLogsServiceRequest
+}
+
+// NewReceiverChannels allocates a ReceiverChannels with reasonably sized
+// buffers so a burst of incoming requests doesn't block the server while
+// ProduceEvents catches up.
+func NewReceiverChannels() *ReceiverChannels {
+	return &ReceiverChannels{
+		Traces:  make(chan *trace.ExportTraceServiceRequest, 64),
+		Metrics: make(chan *collectormetrics.ExportMetricsServiceRequest, 64),
+		Logs:    make(chan *collectorlogs.ExportLogsServiceRequest, 64),
+	}
+}
+
+type traceServiceServer struct {
+	trace.UnimplementedTraceServiceServer
+	out chan<- *trace.ExportTraceServiceRequest
+}
+
+func (s *traceServiceServer) Export(ctx context.Context, req *trace.ExportTraceServiceRequest) (*trace.ExportTraceServiceResponse, error) {
+	s.out <- req
+	return &trace.ExportTraceServiceResponse{}, nil
+}
+
+type metricsServiceServer struct {
+	collectormetrics.UnimplementedMetricsServiceServer
+	out chan<- *collectormetrics.ExportMetricsServiceRequest
+}
+
+func (s *metricsServiceServer) Export(ctx context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	s.out <- req
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+type logsServiceServer struct {
+	collectorlogs.UnimplementedLogsServiceServer
+	out chan<- *collectorlogs.ExportLogsServiceRequest
+}
+
+func (s *logsServiceServer) Export(ctx context.Context, req *collectorlogs.ExportLogsServiceRequest) (*collectorlogs.ExportLogsServiceResponse, error) {
+	s.out <- req
+	return &collectorlogs.ExportLogsServiceResponse{}, nil
+}
+
+// StartGRPCReceiver listens on addr and serves the OTLP trace, metrics, and
+// logs collector gRPC services, forwarding every received request onto the
+// corresponding channel in chans. It returns once the listener is up; the
+// server itself runs in the background until the process exits.
+func StartGRPCReceiver(logger *logrus.Logger, addr string, chans *ReceiverChannels) error {
+	lis, err := net.Listen("tcp", addr)
+
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	server := grpc.NewServer()
+
+	trace.RegisterTraceServiceServer(server, &traceServiceServer{out: chans.Traces})
+	collectormetrics.RegisterMetricsServiceServer(server, &metricsServiceServer{out: chans.Metrics})
+	collectorlogs.RegisterLogsServiceServer(server, &logsServiceServer{out: chans.Logs})
+
+	go func() {
+		logger.WithField("addr", addr).Info("Listening for OTLP gRPC")
+
+		if err := server.Serve(lis); err != nil {
+			logger.WithError(err).Error("OTLP gRPC receiver stopped")
+		}
+	}()
+
+	return nil
+}
+
+// StartHTTPReceiver listens on addr and serves the OTLP/HTTP trace, metrics,
+// and logs endpoints (/v1/traces, /v1/metrics, /v1/logs), accepting either
+// application/x-protobuf or application/json bodies, forwarding every
+// received request onto the corresponding channel in chans.
+func StartHTTPReceiver(logger *logrus.Logger, addr string, chans *ReceiverChannels) error {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/v1/traces", func(w http.ResponseWriter, r *http.Request) {
+		req := &trace.ExportTraceServiceRequest{}
+
+		if !decodeOTLPHTTPBody(logger, w, r, req, func(b []byte) (proto.Message, error) {
+			t, err := ptrace.NewJSONUnmarshaler().UnmarshalTraces(b)
+			if err != nil {
+				return nil, err
+			}
+			pb, err := ptrace.NewProtoMarshaler().MarshalTraces(t)
+			if err != nil {
+				return nil, err
+			}
+			return req, proto.Unmarshal(pb, req)
+		}) {
+			return
+		}
+
+		chans.Traces <- req
+		writeOTLPResponse(w, &trace.ExportTraceServiceResponse{})
+	})
+
+	mux.HandleFunc("/v1/metrics", func(w http.ResponseWriter, r *http.Request) {
+		req := &collectormetrics.ExportMetricsServiceRequest{}
+
+		if !decodeOTLPHTTPBody(logger, w, r, req, func(b []byte) (proto.Message, error) {
+			m, err := pmetric.NewJSONUnmarshaler().UnmarshalMetrics(b)
+			if err != nil {
+				return nil, err
+			}
+			pb, err := pmetric.NewProtoMarshaler().MarshalMetrics(m)
+			if err != nil {
+				return nil, err
+			}
+			return req, proto.Unmarshal(pb, req)
+		}) {
+			return
+		}
+
+		chans.Metrics <- req
+		writeOTLPResponse(w, &collectormetrics.ExportMetricsServiceResponse{})
+	})
+
+	mux.HandleFunc("/v1/logs", func(w http.ResponseWriter, r *http.Request) {
+		req := &collectorlogs.ExportLogsServiceRequest{}
+
+		if !decodeOTLPHTTPBody(logger, w, r, req, func(b []byte) (proto.Message, error) {
+			l, err := plog.NewJSONUnmarshaler().UnmarshalLogs(b)
+			if err != nil {
+				return nil, err
+			}
+			pb, err := plog.NewProtoMarshaler().MarshalLogs(l)
+			if err != nil {
+				return nil, err
+			}
+			return req, proto.Unmarshal(pb, req)
+		}) {
+			return
+		}
+
+		chans.Logs <- req
+		writeOTLPResponse(w, &collectorlogs.ExportLogsServiceResponse{})
+	})
+
+	lis, err := net.Listen("tcp", addr)
+
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	go func() {
+		logger.WithField("addr", addr).Info("Listening for OTLP/HTTP")
+
+		if err := http.Serve(lis, mux); err != nil {
+			logger.WithError(err).Error("OTLP/HTTP receiver stopped")
+		}
+	}()
+
+	return nil
+}
+
+// decodeOTLPHTTPBody reads the request body and unmarshals it into req,
+// either as protobuf directly or, for application/json bodies, via
+// fromJSON. It writes an error response and returns false on failure.
+func decodeOTLPHTTPBody(logger *logrus.Logger, w http.ResponseWriter, r *http.Request, req proto.Message, fromJSON func([]byte) (proto.Message, error)) bool {
+	body, err := io.ReadAll(r.Body)
+
+	if err != nil {
+		logger.WithError(err).Error("Failed to read OTLP/HTTP request body")
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return false
+	}
+
+	if r.Header.Get("Content-Type") == "application/json" {
+		if _, err := fromJSON(body); err != nil {
+			logger.WithError(err).Error("Failed to unmarshal OTLP/HTTP JSON body")
+			http.Error(w, "failed to unmarshal body", http.StatusBadRequest)
+			return false
+		}
+
+		return true
+	}
+
+	if err := proto.Unmarshal(body, req); err != nil {
+		logger.WithError(err).Error("Failed to unmarshal OTLP/HTTP protobuf body")
+		http.Error(w, "failed to unmarshal body", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// runReceiver starts whichever of the gRPC/HTTP OTLP receivers were
+// requested and feeds everything they receive into the same
+// husky-translate -> libhoney pipeline ProcessFile uses for files, running
+// until the process receives an interrupt or termination signal.
+func runReceiver(logger *logrus.Logger, client *libhoney.Client, listen, listenHTTP string, opts ImportOptions, retryTracker *RetryTracker) {
+	chans := NewReceiverChannels()
+
+	if listen != "" {
+		if err := StartGRPCReceiver(logger, listen, chans); err != nil {
+			logger.WithError(err).Fatal("Failed to start OTLP gRPC receiver")
+		}
+	}
+
+	if listenHTTP != "" {
+		if err := StartHTTPReceiver(logger, listenHTTP, chans); err != nil {
+			logger.WithError(err).Fatal("Failed to start OTLP/HTTP receiver")
+		}
+	}
+
+	translated := mergeTranslated(
+		TranslateTraceRequest(logger, chans.Traces),
+		TranslateMetricsRequest(logger, chans.Metrics),
+		TranslateLogsRequest(logger, chans.Logs),
+	)
+
+	events := ProduceEvents(logger, client, opts.Dataset, opts.Batch, opts.MaxBatchBytes, 0, opts.Sleep, translated)
+
+	var count int64
+
+	go func() {
+		for range events {
+			atomic.AddInt64(&count, 1)
+		}
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+
+	retryTracker.Wait()
+	libhoney.Close()
+
+	logger.Infof("Finished: sent %d events, %d permanently failed", atomic.LoadInt64(&count), retryTracker.PermanentlyFailed())
+}
+
+// mergeTranslated fans multiple TranslateOTLPRequestResult channels (one per
+// signal) into a single channel ProduceEvents can consume, closing it once
+// every input channel has closed.
+func mergeTranslated(chans ...<-chan *husky.TranslateOTLPRequestResult) <-chan *husky.TranslateOTLPRequestResult {
+	out := make(chan *husky.TranslateOTLPRequestResult)
+
+	var wg sync.WaitGroup
+
+	for _, c := range chans {
+		wg.Add(1)
+
+		go func(c <-chan *husky.TranslateOTLPRequestResult) {
+			defer wg.Done()
+
+			for r := range c {
+				out <- r
+			}
+		}(c)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+func writeOTLPResponse(w http.ResponseWriter, resp proto.Message) {
+	b, err := proto.Marshal(resp)
+
+	if err != nil {
+		http.Error(w, "failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b)
+}
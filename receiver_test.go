@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	husky "github.com/honeycombio/husky/otlp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	trace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecodeOTLPHTTPBodyProtobuf(t *testing.T) {
+	logger := logrus.New()
+
+	body, err := proto.Marshal(&trace.ExportTraceServiceRequest{})
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/v1/traces", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-protobuf")
+
+	req := &trace.ExportTraceServiceRequest{}
+
+	ok := decodeOTLPHTTPBody(logger, w, r, req, func(b []byte) (proto.Message, error) {
+		t.Fatal("fromJSON should not be called for a protobuf body")
+		return nil, nil
+	})
+
+	assert.True(t, ok)
+}
+
+func TestDecodeOTLPHTTPBodyRejectsGarbage(t *testing.T) {
+	logger := logrus.New()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/v1/traces", bytes.NewReader([]byte{0xff, 0xff, 0xff}))
+	r.Header.Set("Content-Type", "application/x-protobuf")
+
+	req := &trace.ExportTraceServiceRequest{}
+
+	ok := decodeOTLPHTTPBody(logger, w, r, req, func(b []byte) (proto.Message, error) {
+		t.Fatal("fromJSON should not be called for a protobuf body")
+		return nil, nil
+	})
+
+	assert.False(t, ok)
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestMergeTranslated(t *testing.T) {
+	a := make(chan *husky.TranslateOTLPRequestResult, 1)
+	b := make(chan *husky.TranslateOTLPRequestResult, 1)
+
+	a <- &husky.TranslateOTLPRequestResult{}
+	b <- &husky.TranslateOTLPRequestResult{}
+	close(a)
+	close(b)
+
+	out := mergeTranslated(a, b)
+
+	var count int
+
+	for range out {
+		count++
+	}
+
+	assert.Equal(t, 2, count)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/honeycombio/libhoney-go"
+	"github.com/sirupsen/logrus"
+)
+
+// RetryConfig controls how MonitorLibhoneyResponses retries events that
+// libhoney failed to transmit.
+type RetryConfig struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// retryMetadata rides along on an event's Metadata field so the response
+// monitor can re-send the same event on a retryable failure without having
+// to reconstruct it.
+type retryMetadata struct {
+	event   *libhoney.Event
+	attempt int
+}
+
+// isRetryable reports whether a failed libhoney response is worth retrying.
+// 5xx and throttling responses are transient; a missing status code means we
+// never got an HTTP response at all (timeout, connection reset, DNS, etc.)
+// which is also worth retrying. 4xx auth/schema errors are permanent.
+func isRetryable(resp libhoney.Response) bool {
+	if resp.StatusCode == 0 {
+		return true
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return resp.StatusCode >= 500
+}
+
+// retryDelay computes an exponential backoff delay with jitter for the given
+// attempt number, capped at cfg.MaxDelay.
+func retryDelay(cfg RetryConfig, attempt int) time.Duration {
+	delay := cfg.InitialDelay * time.Duration(math.Pow(2, float64(attempt-1)))
+
+	if delay > cfg.MaxDelay || delay <= 0 {
+		delay = cfg.MaxDelay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// RetryTracker requeues retryable transmission failures with exponential
+// backoff and keeps count of events that exhausted their retries.
+type RetryTracker struct {
+	cfg      RetryConfig
+	logger   *logrus.Logger
+	inFlight sync.WaitGroup
+	failed   int64
+}
+
+// NewRetryTracker builds a RetryTracker for the given config.
+func NewRetryTracker(logger *logrus.Logger, cfg RetryConfig) *RetryTracker {
+	return &RetryTracker{cfg: cfg, logger: logger}
+}
+
+// PermanentlyFailed returns the number of events that either hit a
+// non-retryable error or exhausted cfg.MaxAttempts.
+func (rt *RetryTracker) PermanentlyFailed() int64 {
+	return atomic.LoadInt64(&rt.failed)
+}
+
+// Wait blocks until every in-flight retry has been resent (successfully or
+// not). Call it before libhoney.Close() so retries aren't dropped mid-flight.
+func (rt *RetryTracker) Wait() {
+	rt.inFlight.Wait()
+}
+
+// HandleResponse processes a single libhoney transmission response,
+// re-queuing it for retry if appropriate and otherwise counting it as
+// permanently failed.
+//
+// A resend only enqueues the event; it doesn't resolve it. Wait() needs to
+// block until the resend's own response has been handled too, so the
+// inFlight count for a retry isn't released until this method sees that
+// response come back (success, permanent failure, or a further retry handing
+// off to a new Add).
+func (rt *RetryTracker) HandleResponse(resp libhoney.Response) {
+	meta, ok := resp.Metadata.(*retryMetadata)
+	retrying := ok && meta.attempt > 0
+
+	if resp.Err == nil {
+		if retrying {
+			rt.inFlight.Done()
+		}
+
+		return
+	}
+
+	if !ok || meta.event == nil || !isRetryable(resp) || meta.attempt >= rt.cfg.MaxAttempts {
+		rt.logger.WithError(resp.Err).
+			WithField("response", resp).
+			Error("Failed to send event")
+
+		atomic.AddInt64(&rt.failed, 1)
+
+		if retrying {
+			rt.inFlight.Done()
+		}
+
+		return
+	}
+
+	meta.attempt++
+	delay := retryDelay(rt.cfg, meta.attempt)
+
+	rt.logger.WithError(resp.Err).
+		WithField("attempt", meta.attempt).
+		WithField("delay", delay).
+		Warn("Retrying failed event")
+
+	// Add the next attempt's obligation before releasing this one, so Wait()
+	// never observes the tracker empty while a retry chain is still live.
+	rt.inFlight.Add(1)
+
+	if retrying {
+		rt.inFlight.Done()
+	}
+
+	go func() {
+		time.Sleep(delay)
+
+		if err := meta.event.SendPresampled(); err != nil {
+			rt.logger.WithError(err).Error("Failed to re-send event for retry")
+			atomic.AddInt64(&rt.failed, 1)
+			rt.inFlight.Done()
+		}
+	}()
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go"
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, isRetryable(libhoney.Response{StatusCode: 0}))
+	assert.True(t, isRetryable(libhoney.Response{StatusCode: 429}))
+	assert.True(t, isRetryable(libhoney.Response{StatusCode: 503}))
+	assert.False(t, isRetryable(libhoney.Response{StatusCode: 401}))
+	assert.False(t, isRetryable(libhoney.Response{StatusCode: 400}))
+}
+
+func TestRetryTrackerRetriesRetryableFailures(t *testing.T) {
+	logger := logrus.New()
+
+	tx := &transmission.MockSender{}
+
+	client, err := libhoney.NewClient(libhoney.ClientConfig{
+		APIKey:       "test",
+		Transmission: tx,
+	})
+
+	assert.NoError(t, err)
+
+	rt := NewRetryTracker(logger, RetryConfig{
+		MaxAttempts:  2,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     time.Millisecond,
+	})
+
+	// SendPresampled refuses an empty event, so give the resend something to send.
+	event := client.NewEvent()
+	event.AddField("test", "test")
+	event.Dataset = "test"
+	event.Metadata = &retryMetadata{event: event}
+
+	rt.HandleResponse(libhoney.Response{Err: assert.AnError, StatusCode: 503, Metadata: event.Metadata})
+
+	// Give the resend goroutine time to actually call SendPresampled before
+	// we simulate its response; Wait() itself should not return until then.
+	time.Sleep(20 * time.Millisecond)
+
+	rt.HandleResponse(libhoney.Response{Metadata: event.Metadata})
+
+	rt.Wait()
+
+	assert.Equal(t, int64(0), rt.PermanentlyFailed())
+	assert.Len(t, tx.Events(), 1)
+}
+
+func TestRetryTrackerWaitOutlastsNestedRetries(t *testing.T) {
+	logger := logrus.New()
+
+	tx := &transmission.MockSender{}
+
+	client, err := libhoney.NewClient(libhoney.ClientConfig{
+		APIKey:       "test",
+		Transmission: tx,
+	})
+
+	assert.NoError(t, err)
+
+	rt := NewRetryTracker(logger, RetryConfig{
+		MaxAttempts:  3,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     time.Millisecond,
+	})
+
+	// SendPresampled refuses an empty event, so give the resend something to send.
+	event := client.NewEvent()
+	event.AddField("test", "test")
+	event.Dataset = "test"
+	event.Metadata = &retryMetadata{event: event}
+
+	rt.HandleResponse(libhoney.Response{Err: assert.AnError, StatusCode: 503, Metadata: event.Metadata})
+
+	waitReturned := make(chan struct{})
+
+	go func() {
+		rt.Wait()
+		close(waitReturned)
+	}()
+
+	select {
+	case <-waitReturned:
+		t.Fatal("Wait returned before the first-level retry's outcome was known")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// The resend also fails, which hands off to a second-level retry. Wait()
+	// must keep blocking rather than returning as soon as the first resend
+	// was merely enqueued.
+	rt.HandleResponse(libhoney.Response{Err: assert.AnError, StatusCode: 503, Metadata: event.Metadata})
+
+	select {
+	case <-waitReturned:
+		t.Fatal("Wait returned before the second-level retry was resolved")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	rt.HandleResponse(libhoney.Response{Metadata: event.Metadata})
+
+	select {
+	case <-waitReturned:
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after the retry chain resolved")
+	}
+
+	assert.Equal(t, int64(0), rt.PermanentlyFailed())
+}
+
+func TestRetryTrackerGivesUpOnPermanentFailures(t *testing.T) {
+	logger := logrus.New()
+
+	tx := &transmission.MockSender{}
+
+	client, err := libhoney.NewClient(libhoney.ClientConfig{
+		APIKey:       "test",
+		Transmission: tx,
+	})
+
+	assert.NoError(t, err)
+
+	rt := NewRetryTracker(logger, RetryConfig{
+		MaxAttempts:  2,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     time.Millisecond,
+	})
+
+	event := client.NewEvent()
+	event.Metadata = &retryMetadata{event: event}
+
+	rt.HandleResponse(libhoney.Response{Err: assert.AnError, StatusCode: 401, Metadata: event.Metadata})
+
+	rt.Wait()
+
+	assert.Equal(t, int64(1), rt.PermanentlyFailed())
+	assert.Len(t, tx.Events(), 0)
+}
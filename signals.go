@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+
+	husky "github.com/honeycombio/husky/otlp"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// TranslateCollectorMetrics is the metrics-signal counterpart to
+// TranslateCollectorTraces: it round-trips each JSON payload through pdata so
+// we end up with the same ExportMetricsServiceRequest shape husky expects.
+func TranslateCollectorMetrics(logger *logrus.Logger, in <-chan *json.RawMessage) <-chan *collectormetrics.ExportMetricsServiceRequest {
+	out := make(chan *collectormetrics.ExportMetricsServiceRequest)
+	jsonUnmarshaler := pmetric.NewJSONUnmarshaler()
+	protoMarshaler := pmetric.NewProtoMarshaler()
+
+	go func() {
+		defer close(out)
+
+		for rm := range in {
+			m, err := jsonUnmarshaler.UnmarshalMetrics(*rm)
+
+			if err != nil {
+				logger.WithError(err).Error("Failed to unmarshal metrics")
+				break
+			}
+
+			b, err := protoMarshaler.MarshalMetrics(m)
+
+			if err != nil {
+				logger.WithError(err).Error("Failed to marshal metrics")
+				break
+			}
+
+			req := &collectormetrics.ExportMetricsServiceRequest{}
+
+			if err := proto.Unmarshal(b, req); err != nil {
+				logger.WithError(err).Error("Failed to unmarshal ExportMetricsServiceRequest")
+				break
+			}
+
+			out <- req
+		}
+	}()
+
+	return out
+}
+
+// TranslateCollectorLogs is the logs-signal counterpart to
+// TranslateCollectorTraces.
+func TranslateCollectorLogs(logger *logrus.Logger, in <-chan *json.RawMessage) <-chan *collectorlogs.ExportLogsServiceRequest {
+	out := make(chan *collectorlogs.ExportLogsServiceRequest)
+	jsonUnmarshaler := plog.NewJSONUnmarshaler()
+	protoMarshaler := plog.NewProtoMarshaler()
+
+	go func() {
+		defer close(out)
+
+		for rm := range in {
+			l, err := jsonUnmarshaler.UnmarshalLogs(*rm)
+
+			if err != nil {
+				logger.WithError(err).Error("Failed to unmarshal logs")
+				break
+			}
+
+			b, err := protoMarshaler.MarshalLogs(l)
+
+			if err != nil {
+				logger.WithError(err).Error("Failed to marshal logs")
+				break
+			}
+
+			req := &collectorlogs.ExportLogsServiceRequest{}
+
+			if err := proto.Unmarshal(b, req); err != nil {
+				logger.WithError(err).Error("Failed to unmarshal ExportLogsServiceRequest")
+				break
+			}
+
+			out <- req
+		}
+	}()
+
+	return out
+}
+
+// TranslateMetricsRequest is the metrics-signal counterpart to
+// TranslateTraceRequest; it keeps reading in past a bad request for the same
+// live-receiver reason.
+func TranslateMetricsRequest(logger *logrus.Logger, in <-chan *collectormetrics.ExportMetricsServiceRequest) <-chan *husky.TranslateOTLPRequestResult {
+	out := make(chan *husky.TranslateOTLPRequestResult)
+
+	go func() {
+		defer close(out)
+
+		for req := range in {
+			hny, err := husky.TranslateMetricsRequest(req, husky.RequestInfo{
+				ApiKey:      "junk", // we just need a value here. It is not used for anything
+				ContentType: "application/protobuf",
+			})
+
+			if err != nil {
+				logger.WithError(err).Error("Failed to translate metrics")
+				continue
+			}
+
+			out <- hny
+		}
+	}()
+
+	return out
+}
+
+// TranslateLogsRequest is the logs-signal counterpart to
+// TranslateTraceRequest; it keeps reading in past a bad request for the same
+// live-receiver reason.
+func TranslateLogsRequest(logger *logrus.Logger, in <-chan *collectorlogs.ExportLogsServiceRequest) <-chan *husky.TranslateOTLPRequestResult {
+	out := make(chan *husky.TranslateOTLPRequestResult)
+
+	go func() {
+		defer close(out)
+
+		for req := range in {
+			hny, err := husky.TranslateLogsRequest(req, husky.RequestInfo{
+				ApiKey:      "junk", // we just need a value here. It is not used for anything
+				ContentType: "application/protobuf",
+			})
+
+			if err != nil {
+				logger.WithError(err).Error("Failed to translate logs")
+				continue
+			}
+
+			out <- hny
+		}
+	}()
+
+	return out
+}
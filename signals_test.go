@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+)
+
+func TestTranslateCollectorMetrics(t *testing.T) {
+	logger := logrus.New()
+
+	in := make(chan *json.RawMessage, 1)
+
+	simple := `{"resourceMetrics":[{"resource":{"attributes":[]},"scopeMetrics":[]}]}`
+
+	var msg json.RawMessage = []byte(simple)
+
+	in <- &msg
+
+	close(in)
+
+	ch := TranslateCollectorMetrics(logger, in)
+
+	var count int
+
+	for range ch {
+		count++
+	}
+
+	assert.Equal(t, count, 1)
+}
+
+func TestTranslateCollectorLogs(t *testing.T) {
+	logger := logrus.New()
+
+	in := make(chan *json.RawMessage, 1)
+
+	simple := `{"resourceLogs":[{"resource":{"attributes":[]},"scopeLogs":[]}]}`
+
+	var msg json.RawMessage = []byte(simple)
+
+	in <- &msg
+
+	close(in)
+
+	ch := TranslateCollectorLogs(logger, in)
+
+	var count int
+
+	for range ch {
+		count++
+	}
+
+	assert.Equal(t, count, 1)
+}
+
+func TestTranslateMetricsRequest(t *testing.T) {
+	logger := logrus.New()
+
+	in := make(chan *collectormetrics.ExportMetricsServiceRequest, 1)
+
+	msg := collectormetrics.ExportMetricsServiceRequest{}
+
+	in <- &msg
+
+	close(in)
+
+	ch := TranslateMetricsRequest(logger, in)
+
+	var count int
+
+	for range ch {
+		count++
+	}
+
+	assert.Equal(t, count, 1)
+}
+
+func TestTranslateLogsRequest(t *testing.T) {
+	logger := logrus.New()
+
+	in := make(chan *collectorlogs.ExportLogsServiceRequest, 1)
+
+	msg := collectorlogs.ExportLogsServiceRequest{}
+
+	in <- &msg
+
+	close(in)
+
+	ch := TranslateLogsRequest(logger, in)
+
+	var count int
+
+	for range ch {
+		count++
+	}
+
+	assert.Equal(t, count, 1)
+}
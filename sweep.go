@@ -0,0 +1,153 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/honeycombio/libhoney-go"
+	"github.com/sirupsen/logrus"
+)
+
+// doneDirName is the subdirectory files are moved into after they've been
+// successfully transmitted, so a sweep never re-sends them.
+const doneDirName = ".done"
+
+// RunSweep scans dir on sweepInterval for new OTLP files and hands each one
+// to a bounded pool of workers that run the same decode->translate->produce
+// pipeline ProcessFile uses for a single -path file, moving each one to
+// dir/.done on success. It runs until it receives an interrupt or
+// termination signal, at which point it drains in-flight work and flushes
+// retryTracker before returning.
+func RunSweep(logger *logrus.Logger, client *libhoney.Client, dir string, sweepInterval time.Duration, workers int, opts ImportOptions, retryTracker *RetryTracker) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	doneDir := filepath.Join(dir, doneDirName)
+
+	if err := os.MkdirAll(doneDir, 0o755); err != nil {
+		logger.WithError(err).Fatal("Failed to create .done directory")
+	}
+
+	jobs := make(chan string)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var sent int64
+
+	seen := map[string]bool{}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for path := range jobs {
+				count, err := sweepFile(logger, client, dir, doneDir, path, opts)
+
+				atomic.AddInt64(&sent, int64(count))
+
+				if err != nil {
+					mu.Lock()
+					delete(seen, filepath.Base(path))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	scan := func() {
+		entries, err := os.ReadDir(dir)
+
+		if err != nil {
+			logger.WithError(err).Error("Failed to read sweep directory")
+			return
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			name := entry.Name()
+
+			if !strings.HasSuffix(name, ".json") && !strings.HasSuffix(name, ".pb") {
+				continue
+			}
+
+			mu.Lock()
+
+			if seen[name] {
+				mu.Unlock()
+				continue
+			}
+
+			seen[name] = true
+
+			mu.Unlock()
+
+			jobs <- filepath.Join(dir, name)
+		}
+	}
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	scan()
+
+	for {
+		select {
+		case <-sig:
+			close(jobs)
+			wg.Wait()
+
+			retryTracker.Wait()
+			libhoney.Close()
+
+			logger.Infof("Finished: sent %d events, %d permanently failed", atomic.LoadInt64(&sent), retryTracker.PermanentlyFailed())
+
+			return
+		case <-ticker.C:
+			scan()
+		}
+	}
+}
+
+// sweepFile runs the import pipeline for a single swept file and moves it
+// into doneDir on success. On failure it logs and leaves the file in place;
+// the caller clears it from the seen set so the next sweep tick retries it.
+func sweepFile(logger *logrus.Logger, client *libhoney.Client, dir, doneDir, path string, opts ImportOptions) (int, error) {
+	fileOpts := opts
+
+	if fileOpts.Format == "json" && strings.HasSuffix(path, ".pb") {
+		fileOpts.Format = "proto"
+	}
+
+	count, err := ProcessFile(logger, client, path, fileOpts)
+
+	if err != nil {
+		logger.WithError(err).WithField("path", path).Error("Failed to process swept file")
+		return count, err
+	}
+
+	logger.WithField("path", path).WithField("events", count).Info("Processed swept file")
+
+	dest := filepath.Join(doneDir, filepath.Base(path))
+
+	if err := os.Rename(path, dest); err != nil {
+		logger.WithError(err).WithField("path", path).Error("Failed to move swept file to .done")
+		return count, err
+	}
+
+	return count, nil
+}
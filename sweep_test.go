@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/honeycombio/libhoney-go"
+	"github.com/honeycombio/libhoney-go/transmission"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSweepFileMovesToDoneOnSuccess(t *testing.T) {
+	logger := logrus.New()
+
+	tx := &transmission.MockSender{}
+
+	client, err := libhoney.NewClient(libhoney.ClientConfig{
+		APIKey:       "test",
+		Transmission: tx,
+	})
+
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	doneDir := filepath.Join(dir, doneDirName)
+
+	assert.NoError(t, os.MkdirAll(doneDir, 0o755))
+
+	simple := `{"resourceSpans":[]}`
+	path := filepath.Join(dir, "events.json")
+
+	assert.NoError(t, os.WriteFile(path, []byte(simple), 0o644))
+
+	sweepFile(logger, client, dir, doneDir, path, ImportOptions{Format: "json", Signal: "traces"})
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = os.Stat(filepath.Join(doneDir, "events.json"))
+	assert.NoError(t, err)
+}
+
+func TestRunSweepProcessesNewFiles(t *testing.T) {
+	logger := logrus.New()
+
+	tx := &transmission.MockSender{}
+
+	client, err := libhoney.NewClient(libhoney.ClientConfig{
+		APIKey:       "test",
+		Transmission: tx,
+	})
+
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+
+	simple := `{"resourceSpans":[]}`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "events.json"), []byte(simple), 0o644))
+
+	retryTracker := NewRetryTracker(logger, RetryConfig{MaxAttempts: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	go RunSweep(logger, client, dir, 10*time.Millisecond, 2, ImportOptions{Format: "json", Signal: "traces"}, retryTracker)
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, doneDirName, "events.json"))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRunSweepRetriesFileThatFailedToProcess(t *testing.T) {
+	logger, hook := logrustest.NewNullLogger()
+
+	tx := &transmission.MockSender{}
+
+	client, err := libhoney.NewClient(libhoney.ClientConfig{
+		APIKey:       "test",
+		Transmission: tx,
+	})
+
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bad.json"), []byte("x"), 0o644))
+
+	retryTracker := NewRetryTracker(logger, RetryConfig{MaxAttempts: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	// -format proto only supports -signal traces, so every sweep of bad.json
+	// fails in ProcessFile before the file is ever moved to .done.
+	go RunSweep(logger, client, dir, 10*time.Millisecond, 1, ImportOptions{Format: "proto", Signal: "metrics"}, retryTracker)
+
+	assert.Eventually(t, func() bool {
+		failures := 0
+
+		for _, e := range hook.AllEntries() {
+			if e.Message == "Failed to process swept file" {
+				failures++
+			}
+		}
+
+		return failures >= 2
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = os.Stat(filepath.Join(dir, "bad.json"))
+	assert.NoError(t, err)
+}
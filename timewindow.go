@@ -0,0 +1,82 @@
+package main
+
+import (
+	"time"
+
+	husky "github.com/honeycombio/husky/otlp"
+	"github.com/sirupsen/logrus"
+)
+
+// honeycombIngestWindow is a conservative approximation of how far in the
+// past Honeycomb will still accept an event's timestamp. It's intentionally
+// on the safe side since the real limit depends on account configuration;
+// -preserve-duration uses it only to warn, never to drop events itself.
+const honeycombIngestWindow = 7 * 24 * time.Hour
+
+// BufferAndComputeAdjustment drains in fully, buffering every translated
+// result, and returns a replacement channel that replays them in the same
+// order along with the time-shift adjustment needed to slide the whole trace
+// as a rigid block so it ends at now-start.
+func BufferAndComputeAdjustment(logger *logrus.Logger, start time.Duration, in <-chan *husky.TranslateOTLPRequestResult) (<-chan *husky.TranslateOTLPRequestResult, time.Duration, time.Time) {
+	out := make(chan *husky.TranslateOTLPRequestResult)
+
+	var buffered []*husky.TranslateOTLPRequestResult
+	var minTimestamp, maxTimestamp time.Time
+
+	for r := range in {
+		buffered = append(buffered, r)
+
+		for _, b := range r.Batches {
+			for _, e := range b.Events {
+				if minTimestamp.IsZero() || e.Timestamp.Before(minTimestamp) {
+					minTimestamp = e.Timestamp
+				}
+
+				if e.Timestamp.After(maxTimestamp) {
+					maxTimestamp = e.Timestamp
+				}
+			}
+		}
+	}
+
+	var adjustment time.Duration
+
+	if start > 0 && !maxTimestamp.IsZero() {
+		adjustment = time.Now().Add(-start).Sub(maxTimestamp)
+
+		logger.WithFields(logrus.Fields{
+			"min":        minTimestamp,
+			"max":        maxTimestamp,
+			"adjustment": adjustment,
+		}).Debug("Computed time-shift adjustment for the whole trace")
+	}
+
+	go func() {
+		defer close(out)
+
+		for _, r := range buffered {
+			out <- r
+		}
+	}()
+
+	return out, adjustment, minTimestamp
+}
+
+// WarnIfOutsideIngestWindow logs a warning if the earliest event in the
+// (already adjusted) trace falls outside Honeycomb's ingest window, meaning
+// it would likely be rejected as too old once -start has slid the whole
+// trace into place.
+func WarnIfOutsideIngestWindow(logger *logrus.Logger, shiftedMin time.Time) {
+	if shiftedMin.IsZero() {
+		return
+	}
+
+	oldest := time.Now().Add(-honeycombIngestWindow)
+
+	if shiftedMin.Before(oldest) {
+		logger.WithFields(logrus.Fields{
+			"oldestEvent": shiftedMin,
+			"ingestLimit": oldest,
+		}).Warn("Earliest event in the trace falls outside Honeycomb's ingest window and may be dropped")
+	}
+}
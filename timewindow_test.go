@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	husky "github.com/honeycombio/husky/otlp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferAndComputeAdjustmentSlidesWholeTraceAsRigidBlock(t *testing.T) {
+	logger := logrus.New()
+
+	now := time.Now()
+	early := now.Add(-2 * time.Hour)
+	late := now.Add(-1 * time.Hour)
+
+	in := make(chan *husky.TranslateOTLPRequestResult, 2)
+
+	// deliberately out of timestamp order: the later event arrives first on
+	// the channel, mirroring how file order need not match timestamp order.
+	in <- &husky.TranslateOTLPRequestResult{Batches: []husky.Batch{{Events: []husky.Event{{Timestamp: late}}}}}
+	in <- &husky.TranslateOTLPRequestResult{Batches: []husky.Batch{{Events: []husky.Event{{Timestamp: early}}}}}
+	close(in)
+
+	start := 10 * time.Minute
+
+	out, adjustment, minTimestamp := BufferAndComputeAdjustment(logger, start, in)
+
+	assert.Equal(t, early, minTimestamp)
+
+	var shiftedMax time.Time
+
+	for r := range out {
+		for _, b := range r.Batches {
+			for _, e := range b.Events {
+				shifted := e.Timestamp.Add(adjustment)
+
+				if shifted.After(shiftedMax) {
+					shiftedMax = shifted
+				}
+			}
+		}
+	}
+
+	assert.WithinDuration(t, time.Now().Add(-start), shiftedMax, time.Second)
+}
+
+func TestWarnIfOutsideIngestWindow(t *testing.T) {
+	logger := logrus.New()
+
+	// neither call should panic; there's no return value to assert on, this
+	// just exercises both branches.
+	WarnIfOutsideIngestWindow(logger, time.Now())
+	WarnIfOutsideIngestWindow(logger, time.Now().Add(-30*24*time.Hour))
+}